@@ -1,9 +1,9 @@
 package parse
 
 import (
+	"context"
 	"crypto/sha1"
 	"encoding/hex"
-	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -11,7 +11,6 @@ import (
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
-	goose "github.com/advancedlogic/GoOse"
 	"github.com/google/uuid"
 	"github.com/microcosm-cc/bluemonday"
 
@@ -19,14 +18,15 @@ import (
 )
 
 var p = bluemonday.UGCPolicy()
-var g = goose.New()
 
-var tr = &http.Transport{
-	IdleConnTimeout: 5 * time.Second,
-}
+// cache is the Cache consulted by Parse to skip items it has already
+// extracted. It is nil (disabled) until a caller opts in via SetCache.
+var cache Cache
 
-var client = &http.Client{
-	Transport: tr,
+// SetCache installs the Cache Parse should use for seen-item tracking and
+// per-feed failure bookkeeping. Pass nil to disable caching.
+func SetCache(c Cache) {
+	cache = c
 }
 
 type RrssFeed struct {
@@ -38,52 +38,175 @@ type RrssFeed struct {
 	ItemBody         string
 	ItemUrl          string
 	ItemExtendedBody string
+	ItemEmbedHTML    string
+	ItemAuthor       string
 	Published        string
 	Created          time.Time
 }
 
-func Parse(url string) ([]RrssFeed, error) {
+// Parse fetches the feed at url and returns one RrssFeed per item, with up
+// to opts.Concurrency items being fetched and extracted at a time. Pass
+// DefaultParseOptions() for sane defaults.
+//
+// Items are left out of the result when no id can be generated for them or
+// when their article extraction still fails after opts.MaxRetries retries.
+// When the server answers 304 Not Modified, Parse returns no items and a nil
+// error; previously parsed items are not kept or returned. While a cache is
+// set, a failed fetch or parse also returns no items and a nil error until
+// the feed has failed more than MaxFailures times in a row.
+//
+// If url is an HTML page rather than a feed, Parse falls back to
+// autodiscovery and returns the feed URL it discovered and parsed as
+// discoveredFeedURL; otherwise discoveredFeedURL is empty.
+func Parse(ctx context.Context, url string, opts ParseOptions) (items []RrssFeed, discoveredFeedURL string, err error) {
+	return parse(ctx, url, opts, map[string]bool{})
+}
+
+// parse is Parse's actual implementation. visited carries the set of URLs
+// already tried on this call's autodiscovery chain, so discoverAndParse can
+// detect cycles (two pages that advertise each other as their feed) instead
+// of recursing forever.
+func parse(ctx context.Context, url string, opts ParseOptions, visited map[string]bool) (items []RrssFeed, discoveredFeedURL string, err error) {
+	opts = opts.withDefaults()
+
 	// Verify input URL
 	log.Println("Received feed url: ", url)
 
+	var state FeedState
+	if cache != nil {
+		state = cache.FeedState(url)
+	}
+
+	resp, err := conditionalGet(ctx, url, state, opts.PerRequestTimeout, opts.MaxBodyBytes)
+	if err == nil && resp.StatusCode == http.StatusNotModified {
+		drainAndClose(resp.Body)
+		log.Printf("%v not modified since last fetch, skipping", url)
+		return nil, "", nil
+	}
+	if err == nil && resp.StatusCode >= 400 {
+		err = fmt.Errorf("fetching %v: unexpected status %d", url, resp.StatusCode)
+	}
+	if err != nil {
+		if cache != nil {
+			state.ConsecutiveFails++
+			cache.UpdateFeedState(url, state)
+
+			if state.ConsecutiveFails <= MaxFailures {
+				log.Printf("Warning: fetch failed for %v (%d/%d consecutive failures): %v", url, state.ConsecutiveFails, MaxFailures, err)
+				return nil, "", nil
+			}
+			log.Printf("%v has failed %d times in a row, giving up: %v", url, state.ConsecutiveFails, err)
+		}
+		log.Println(err.Error())
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
 	fp := gofeed.NewParser()
-	feed, err := fp.ParseURL(url)
+	feed, err := fp.Parse(resp.Body)
+	if err == gofeed.ErrFeedTypeNotDetected {
+		log.Printf("%v is not a feed, looking for autodiscovery links", url)
+		return discoverAndParse(ctx, url, opts, visited)
+	}
 	if err != nil {
+		if cache != nil {
+			state.ConsecutiveFails++
+			cache.UpdateFeedState(url, state)
+
+			if state.ConsecutiveFails <= MaxFailures {
+				log.Printf("Warning: parsing %v failed (%d/%d consecutive failures): %v", url, state.ConsecutiveFails, MaxFailures, err)
+				return nil, "", nil
+			}
+			log.Printf("%v has failed %d times in a row, giving up: %v", url, state.ConsecutiveFails, err)
+		}
 		log.Println(err.Error())
-		return nil, err
+		return nil, "", err
+	}
+
+	if cache != nil {
+		cache.UpdateFeedState(url, nextFeedState(resp))
 	}
 	log.Printf("Parsing %v", feed.Title)
 	log.Printf("Found %v items in feed", len(feed.Items))
 
-	// Build Feed objects
-	feedItems := make([]RrssFeed, 0)
+	// Build Feed objects, bounding how many items are fetched concurrently.
+	// Each worker writes only to its own index, so no lock is needed.
+	results := make([]*resultItem, len(feed.Items))
 	sliceLength := len(feed.Items)
+	sem := make(chan struct{}, opts.Concurrency)
 	var wg sync.WaitGroup
 	wg.Add(sliceLength)
 	for i := 0; i < sliceLength; i++ {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Done()
+			continue
+		}
+
 		go func(i int) {
 			defer wg.Done()
+			defer func() { <-sem }()
+
 			item := feed.Items[i]
 			// Generate ID for the item
 			id, err := generateId(item)
 			if err != nil {
-				log.Fatal("Failed to generate ID for item", err)
+				log.Printf("Failed to generate ID for item %d, skipping: %v", i, err)
+				return
 			}
 
 			itemExtended := ""
 			itemImage := ""
+			itemTitle := item.Title
+			itemAuthor := ""
+			itemEmbedHTML := ""
+			itemPublished := item.Published
 			// Fetch full article
 			itemUrl := item.Link
-			if len(itemUrl) > 0 {
+			if cache != nil && cache.Seen(id) {
+				log.Printf("Skipping already-seen item (id '%s', title '%s')", id, item.Title)
+			} else if len(itemUrl) > 0 {
 				log.Printf("Fetching extended article for '%s'", itemUrl)
-				article, err := extractArticle(itemUrl)
+
+				var article *Article
+				for attempt := 0; attempt <= opts.MaxRetries; attempt++ {
+					article, err = extractArticle(ctx, itemUrl, opts)
+					if err == nil {
+						break
+					}
+					log.Printf("Attempt %d/%d failed to extract '%s': %v", attempt+1, opts.MaxRetries+1, itemUrl, err)
+				}
+				if err != nil {
+					log.Printf("Giving up on '%s' after %d attempts, dropping item", itemUrl, opts.MaxRetries+1)
+					return
+				}
+				if article != nil {
+					itemExtended = article.Text
+					itemImage = article.TopImage
+				}
+
+				enrichment, err := enrichFromHTML(ctx, itemUrl, opts)
 				if err != nil {
 					log.Println(err)
 				}
-				itemExtended = article.CleanedText
-				itemImage = article.TopImage
+				if enrichment != nil {
+					if itemTitle == "" {
+						itemTitle = enrichment.Title
+					}
+					if itemImage == "" {
+						itemImage = enrichment.Image
+					}
+					if itemPublished == "" {
+						itemPublished = enrichment.Published
+					}
+					itemAuthor = enrichment.Author
+					itemEmbedHTML = enrichment.EmbedHTML
+				}
 
-				time.Sleep(1 * time.Second) // Wait for 1 second before getting next item
+				if cache != nil {
+					cache.MarkSeen(id)
+				}
 			} else {
 				log.Printf("Item has no link, skip fetching extended (id '%s', title '%s')", id, item.Title)
 			}
@@ -92,26 +215,51 @@ func Parse(url string) ([]RrssFeed, error) {
 			// Strip html from body and extended body
 			itemDescription := p.Sanitize(item.Description)
 
-			// Put it in the array
-			feedItems = append(feedItems, RrssFeed{
-				Id:               id,
-				FeedUrl:          string(url),
-				FeedTitle:        string(feed.Title),
-				ItemBody:         itemDescription,
-				ItemUrl:          item.Link,
-				Published:        item.Published,
-				ItemExtendedBody: itemExtended,
-				ItemImage:        itemImage,
-				Created:          time.Now(),
-			})
+			var published time.Time
+			if item.PublishedParsed != nil {
+				published = *item.PublishedParsed
+			}
+
+			// Each worker only ever touches results[i], so this is race-free.
+			results[i] = &resultItem{
+				published: published,
+				feed: RrssFeed{
+					Id:               id,
+					FeedUrl:          string(url),
+					FeedTitle:        string(feed.Title),
+					ItemTitle:        itemTitle,
+					ItemBody:         itemDescription,
+					ItemUrl:          item.Link,
+					Published:        itemPublished,
+					ItemExtendedBody: itemExtended,
+					ItemImage:        itemImage,
+					ItemEmbedHTML:    itemEmbedHTML,
+					ItemAuthor:       itemAuthor,
+					Created:          time.Now(),
+				},
+			}
 
 			log.Printf("Id=%v : Url=%v : Title=%v Extended (char count)=%v Item no: %d/%d", id, string(url), string(feed.Title), len(itemExtended), i, sliceLength)
 		}(i)
 	}
 
 	wg.Wait()
+
+	kept := make([]resultItem, 0, sliceLength)
+	for _, r := range results {
+		if r != nil {
+			kept = append(kept, *r)
+		}
+	}
+	sortResults(kept, opts.SortBy)
+
+	feedItems := make([]RrssFeed, len(kept))
+	for i, r := range kept {
+		feedItems[i] = r.feed
+	}
+
 	log.Printf("Parsed %v items in %s", len(feedItems), url)
-	return feedItems, nil
+	return feedItems, "", nil
 }
 
 func hashContent(content string) string {
@@ -142,23 +290,25 @@ func generateId(item *gofeed.Item) (string, error) {
 	return uuid.String(), nil
 }
 
-func extractArticle(url string) (*goose.Article, error) {
-	article, err := g.ExtractFromURL(url)
-	return article, err
-}
+func GetExtendedArticle(ctx context.Context, link string, opts ParseOptions) (string, error) {
+	opts = opts.withDefaults()
 
-func GetExtendedArticle(link string) (string, error) {
-	response, err := http.Get(link)
-	if err != nil {
-		return "", err
+	var state FeedState
+	if cache != nil {
+		state = cache.FeedState(link)
 	}
 
+	response, err := conditionalGet(ctx, link, state, opts.PerRequestTimeout, opts.MaxBodyBytes)
 	if err != nil {
-		log.Fatal(err)
+		return "", err
 	}
-
 	defer response.Body.Close()
 
+	if response.StatusCode == http.StatusNotModified {
+		log.Printf("%s not modified since last fetch, using cached text", link)
+		return state.CachedText, nil
+	}
+
 	if response.StatusCode >= 200 && response.StatusCode <= 299 {
 		doc, err := goquery.NewDocumentFromResponse(response)
 		if err != nil {
@@ -177,7 +327,14 @@ func GetExtendedArticle(link string) (string, error) {
 			}
 		})
 		log.Printf("%s responded with status code %d. Body is %d chars long", link, response.StatusCode, len(article))
+
+		if cache != nil {
+			next := nextFeedState(response)
+			next.CachedText = article
+			cache.UpdateFeedState(link, next)
+		}
+
 		return article, nil
 	}
-	return "", errors.New(fmt.Sprintf("Expected 2XX status code but received '%d'", response.StatusCode))
+	return "", fmt.Errorf("expected 2XX status code but received '%d'", response.StatusCode)
 }
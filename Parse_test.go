@@ -0,0 +1,193 @@
+package parse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeCache is a minimal in-memory Cache for tests that need to observe
+// FeedState bookkeeping without touching disk.
+type fakeCache struct {
+	mu    sync.Mutex
+	seen  map[string]bool
+	feeds map[string]FeedState
+}
+
+func newFakeCache() *fakeCache {
+	return &fakeCache{seen: map[string]bool{}, feeds: map[string]FeedState{}}
+}
+
+func (c *fakeCache) Seen(id string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.seen[id]
+}
+
+func (c *fakeCache) MarkSeen(id string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seen[id] = true
+	return nil
+}
+
+func (c *fakeCache) FeedState(feedUrl string) FeedState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.feeds[feedUrl]
+}
+
+func (c *fakeCache) UpdateFeedState(feedUrl string, state FeedState) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.feeds[feedUrl] = state
+	return nil
+}
+
+func (c *fakeCache) ReapOlderThan(d time.Duration) error { return nil }
+
+func TestParseDowngradesMalformedFeedErrorsToWarnings(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Detected as RSS by gofeed's type sniffing, but malformed enough
+		// that the actual parse fails.
+		w.Write([]byte(`<?xml version="1.0"?><rss version="2.0"><channel><title>Bad`))
+	}))
+	defer srv.Close()
+
+	c := newFakeCache()
+	SetCache(c)
+	defer SetCache(nil)
+
+	for i := 1; i <= MaxFailures; i++ {
+		items, discovered, err := Parse(context.Background(), srv.URL, ParseOptions{})
+		if err != nil {
+			t.Fatalf("Parse() attempt %d error = %v, want nil (still under MaxFailures)", i, err)
+		}
+		if items != nil || discovered != "" {
+			t.Fatalf("Parse() attempt %d = (%v, %q), want (nil, \"\")", i, items, discovered)
+		}
+		if got := c.FeedState(srv.URL).ConsecutiveFails; got != i {
+			t.Fatalf("ConsecutiveFails after attempt %d = %d, want %d", i, got, i)
+		}
+	}
+
+	_, _, err := Parse(context.Background(), srv.URL, ParseOptions{})
+	if err == nil {
+		t.Fatal("Parse() error = nil after exceeding MaxFailures, want an error")
+	}
+}
+
+// byPathExtractor returns a canned Article (or error) per request path,
+// letting a test drive Parse's worker pool without depending on goose or
+// go-readability's real-world extraction heuristics.
+type byPathExtractor struct {
+	articles map[string]*Article
+	errors   map[string]error
+}
+
+func (byPathExtractor) Name() string { return "by-path" }
+
+func (e byPathExtractor) Extract(ctx context.Context, pageUrl string, opts ParseOptions) (*Article, error) {
+	parsed, err := url.Parse(pageUrl)
+	if err != nil {
+		return nil, err
+	}
+	if err, ok := e.errors[parsed.Path]; ok {
+		return nil, err
+	}
+	return e.articles[parsed.Path], nil
+}
+
+func TestParseFetchesSortsDropsAndSkipsItems(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	for _, path := range []string{"/article-a", "/article-b"} {
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("<html></html>"))
+		})
+	}
+
+	mux.HandleFunc("/feed.xml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		fmt.Fprintf(w, `<?xml version="1.0"?><rss version="2.0"><channel>
+			<title>Test Feed</title>
+			<item><title>Item A</title><guid>item-a</guid><link>%[1]s/article-a</link><pubDate>Wed, 01 Jan 2020 00:00:00 GMT</pubDate><description>Desc A</description></item>
+			<item><title>Item B</title><guid>item-b</guid><link>%[1]s/article-b</link><pubDate>Thu, 01 Jan 2021 00:00:00 GMT</pubDate><description>Desc B</description></item>
+			<item><title>Item C (fails)</title><guid>item-c</guid><link>%[1]s/article-c-fails</link><pubDate>Fri, 01 Jan 2022 00:00:00 GMT</pubDate><description>Desc C</description></item>
+			<item><title>Item D (cached)</title><guid>item-d</guid><link>%[1]s/article-d</link><pubDate>Sat, 01 Jan 2023 00:00:00 GMT</pubDate><description>Desc D</description></item>
+		</channel></rss>`, srv.URL)
+	})
+
+	host := mustHost(t, srv.URL)
+	RegisterExtractor(host, byPathExtractor{
+		articles: map[string]*Article{
+			"/article-a": {Text: "Full text A"},
+			"/article-b": {Text: "Full text B"},
+		},
+		errors: map[string]error{
+			"/article-c-fails": errors.New("extraction always fails"),
+		},
+	})
+
+	c := newFakeCache()
+	if err := c.MarkSeen("item-d"); err != nil {
+		t.Fatalf("MarkSeen() error = %v", err)
+	}
+	SetCache(c)
+	defer SetCache(nil)
+
+	items, _, err := Parse(context.Background(), srv.URL+"/feed.xml", ParseOptions{
+		Concurrency: 2,
+		MaxRetries:  1,
+		SortBy:      SortPublishedDesc,
+	})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if len(items) != 3 {
+		t.Fatalf("Parse() returned %d items, want 3 (item C should be dropped after exhausting retries): %+v", len(items), items)
+	}
+
+	wantOrder := []string{"item-d", "item-b", "item-a"}
+	for i, id := range wantOrder {
+		if items[i].Id != id {
+			t.Fatalf("items[%d].Id = %q, want %q (want published-desc order %v)", i, items[i].Id, id, wantOrder)
+		}
+	}
+
+	byId := map[string]RrssFeed{}
+	for _, item := range items {
+		byId[item.Id] = item
+	}
+
+	if got := byId["item-a"].ItemExtendedBody; got != "Full text A" {
+		t.Errorf("item-a ItemExtendedBody = %q, want %q", got, "Full text A")
+	}
+	if got := byId["item-b"].ItemExtendedBody; got != "Full text B" {
+		t.Errorf("item-b ItemExtendedBody = %q, want %q", got, "Full text B")
+	}
+	if got := byId["item-d"].ItemExtendedBody; got != "" {
+		t.Errorf("item-d ItemExtendedBody = %q, want empty (cache-skipped, never extracted)", got)
+	}
+	if got := byId["item-d"].ItemTitle; got != "Item D (cached)" {
+		t.Errorf("item-d ItemTitle = %q, want feed title preserved for a cache-skipped item", got)
+	}
+}
+
+func mustHost(t *testing.T, rawUrl string) string {
+	t.Helper()
+	parsed, err := url.Parse(rawUrl)
+	if err != nil {
+		t.Fatalf("url.Parse(%q) error = %v", rawUrl, err)
+	}
+	return parsed.Host
+}
@@ -0,0 +1,145 @@
+package parse
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// MaxFailures is the number of consecutive fetch failures a feed may accrue
+// before Parse escalates from a logged warning to a returned error.
+const MaxFailures = 5
+
+// FeedState holds the conditional-request and health bookkeeping Parse keeps
+// for a single feed URL between runs.
+type FeedState struct {
+	LastFetch        time.Time
+	ETag             string
+	LastModified     string
+	ConsecutiveFails int
+	// CachedText holds the last successfully extracted body for this URL,
+	// so a 304 response can be served from cache instead of refetched.
+	CachedText string
+}
+
+// Cache lets Parse avoid refetching items it has already seen (keyed by the
+// id produced in generateId) and tracks per-feed fetch health across runs.
+type Cache interface {
+	// Seen reports whether id has already been cached.
+	Seen(id string) bool
+	// MarkSeen records id as having been processed.
+	MarkSeen(id string) error
+	// FeedState returns the last known state for feedUrl, or the zero value
+	// if it has never been recorded.
+	FeedState(feedUrl string) FeedState
+	// UpdateFeedState replaces the stored state for feedUrl.
+	UpdateFeedState(feedUrl string, state FeedState) error
+	// ReapOlderThan evicts cached items first seen more than d ago.
+	ReapOlderThan(d time.Duration) error
+}
+
+// seenItem records when an item was first cached, so ReapOlderThan knows
+// which entries are stale.
+type seenItem struct {
+	FirstSeen time.Time `json:"first_seen"`
+}
+
+// jsonCache is the default Cache implementation: a JSON file on disk,
+// rewritten after every mutation and guarded by a mutex.
+type jsonCache struct {
+	mu   sync.Mutex
+	path string
+
+	Items map[string]seenItem  `json:"items"`
+	Feeds map[string]FeedState `json:"feeds"`
+}
+
+// NewJSONCache opens the cache stored at path, creating an empty one if the
+// file does not yet exist.
+func NewJSONCache(path string) (Cache, error) {
+	c := &jsonCache{
+		path:  path,
+		Items: make(map[string]seenItem),
+		Feeds: make(map[string]FeedState),
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c, nil
+		}
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (c *jsonCache) Seen(id string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, ok := c.Items[id]
+	return ok
+}
+
+func (c *jsonCache) MarkSeen(id string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.Items[id]; !ok {
+		c.Items[id] = seenItem{FirstSeen: time.Now()}
+	}
+	return c.save()
+}
+
+func (c *jsonCache) FeedState(feedUrl string) FeedState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.Feeds[feedUrl]
+}
+
+func (c *jsonCache) UpdateFeedState(feedUrl string, state FeedState) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.Feeds[feedUrl] = state
+	return c.save()
+}
+
+func (c *jsonCache) ReapOlderThan(d time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cutoff := time.Now().Add(-d)
+	for id, item := range c.Items {
+		if item.FirstSeen.Before(cutoff) {
+			delete(c.Items, id)
+		}
+	}
+	return c.save()
+}
+
+// save persists the cache to disk. Callers must hold c.mu.
+func (c *jsonCache) save() error {
+	if c.path == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(c.path, data, 0o644)
+}
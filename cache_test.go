@@ -0,0 +1,88 @@
+package parse
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestJSONCacheSeenAndMarkSeen(t *testing.T) {
+	c, err := NewJSONCache(filepath.Join(t.TempDir(), "cache.json"))
+	if err != nil {
+		t.Fatalf("NewJSONCache() error = %v", err)
+	}
+
+	if c.Seen("id-1") {
+		t.Fatal("Seen() = true before MarkSeen, want false")
+	}
+
+	if err := c.MarkSeen("id-1"); err != nil {
+		t.Fatalf("MarkSeen() error = %v", err)
+	}
+
+	if !c.Seen("id-1") {
+		t.Fatal("Seen() = false after MarkSeen, want true")
+	}
+}
+
+func TestJSONCacheFeedState(t *testing.T) {
+	c, err := NewJSONCache(filepath.Join(t.TempDir(), "cache.json"))
+	if err != nil {
+		t.Fatalf("NewJSONCache() error = %v", err)
+	}
+
+	if got := c.FeedState("https://example.com/feed"); got != (FeedState{}) {
+		t.Fatalf("FeedState() = %+v, want zero value for unknown feed", got)
+	}
+
+	state := FeedState{ETag: `"abc"`, ConsecutiveFails: 2}
+	if err := c.UpdateFeedState("https://example.com/feed", state); err != nil {
+		t.Fatalf("UpdateFeedState() error = %v", err)
+	}
+
+	if got := c.FeedState("https://example.com/feed"); got != state {
+		t.Fatalf("FeedState() = %+v, want %+v", got, state)
+	}
+}
+
+func TestJSONCachePersistsAcrossReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+
+	c, err := NewJSONCache(path)
+	if err != nil {
+		t.Fatalf("NewJSONCache() error = %v", err)
+	}
+	if err := c.MarkSeen("id-1"); err != nil {
+		t.Fatalf("MarkSeen() error = %v", err)
+	}
+
+	reopened, err := NewJSONCache(path)
+	if err != nil {
+		t.Fatalf("NewJSONCache() reopen error = %v", err)
+	}
+	if !reopened.Seen("id-1") {
+		t.Fatal("Seen() = false after reopening cache file, want true")
+	}
+}
+
+func TestJSONCacheReapOlderThan(t *testing.T) {
+	c, err := NewJSONCache(filepath.Join(t.TempDir(), "cache.json"))
+	if err != nil {
+		t.Fatalf("NewJSONCache() error = %v", err)
+	}
+
+	jc := c.(*jsonCache)
+	jc.Items["old"] = seenItem{FirstSeen: time.Now().Add(-2 * time.Hour)}
+	jc.Items["new"] = seenItem{FirstSeen: time.Now()}
+
+	if err := c.ReapOlderThan(time.Hour); err != nil {
+		t.Fatalf("ReapOlderThan() error = %v", err)
+	}
+
+	if c.Seen("old") {
+		t.Error("Seen(\"old\") = true after reaping, want false")
+	}
+	if !c.Seen("new") {
+		t.Error("Seen(\"new\") = false after reaping, want true")
+	}
+}
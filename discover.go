@@ -0,0 +1,124 @@
+package parse
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// feedLinkTypes are the <link type="..."> values that mark an alternate
+// feed, per the RSS/Atom/JSON Feed autodiscovery convention.
+var feedLinkTypes = map[string]bool{
+	"application/rss+xml":   true,
+	"application/atom+xml":  true,
+	"application/feed+json": true,
+}
+
+// Discover fetches pageUrl as HTML and returns the absolute URLs of any
+// alternate feed links it advertises (<link rel="alternate" type="...">),
+// in document order. The request is bound to ctx and opts' per-request
+// timeout and body size cap, the same as Parse's own fetches.
+func Discover(ctx context.Context, pageUrl string, opts ParseOptions) ([]string, error) {
+	opts = opts.withDefaults()
+
+	resp, err := conditionalGet(ctx, pageUrl, FeedState{}, opts.PerRequestTimeout, opts.MaxBodyBytes)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("discovering feeds at %v: unexpected status %d", pageUrl, resp.StatusCode)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var feeds []string
+	doc.Find("link[rel=alternate]").Each(func(i int, s *goquery.Selection) {
+		feedType, _ := s.Attr("type")
+		if !feedLinkTypes[strings.ToLower(feedType)] {
+			return
+		}
+
+		href, ok := s.Attr("href")
+		if !ok {
+			return
+		}
+
+		if resolved, ok := resolveURL(pageUrl, href); ok {
+			feeds = append(feeds, resolved)
+		}
+	})
+
+	return feeds, nil
+}
+
+// resolveURL resolves ref against baseUrl, returning false if either fails
+// to parse.
+func resolveURL(baseUrl, ref string) (string, bool) {
+	base, err := url.Parse(baseUrl)
+	if err != nil {
+		return "", false
+	}
+
+	parsed, err := url.Parse(ref)
+	if err != nil {
+		return "", false
+	}
+
+	return base.ResolveReference(parsed).String(), true
+}
+
+// maxDiscoveryDepth bounds how many autodiscovery hops discoverAndParse will
+// follow from the original Parse call, as a backstop against cycles too
+// convoluted for the visited set to make obviously pointless to keep
+// exploring.
+const maxDiscoveryDepth = 5
+
+// discoverAndParse treats pageUrl as an HTML page, discovers the feeds it
+// advertises, and parses the first one that succeeds, returning the feed
+// URL it used alongside the parsed items. visited is checked and updated to
+// guard against two pages that advertise each other as their feed, which
+// would otherwise recurse through Parse and discoverAndParse forever.
+func discoverAndParse(ctx context.Context, pageUrl string, opts ParseOptions, visited map[string]bool) (items []RrssFeed, discoveredFeedURL string, err error) {
+	if visited[pageUrl] {
+		return nil, "", fmt.Errorf("autodiscovery cycle detected at %v", pageUrl)
+	}
+	if len(visited) >= maxDiscoveryDepth {
+		return nil, "", fmt.Errorf("autodiscovery exceeded max depth (%d) at %v", maxDiscoveryDepth, pageUrl)
+	}
+	visited[pageUrl] = true
+
+	candidates, err := Discover(ctx, pageUrl, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(candidates) == 0 {
+		return nil, "", fmt.Errorf("no feed links discovered at %v", pageUrl)
+	}
+
+	var lastErr error
+	for _, candidate := range candidates {
+		if visited[candidate] {
+			lastErr = fmt.Errorf("autodiscovery cycle detected at %v", candidate)
+			continue
+		}
+
+		log.Printf("Discovered candidate feed %v from %v", candidate, pageUrl)
+		items, _, err := parse(ctx, candidate, opts, visited)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return items, candidate, nil
+	}
+
+	return nil, "", fmt.Errorf("no discovered feed at %v could be parsed: %w", pageUrl, lastErr)
+}
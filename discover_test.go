@@ -0,0 +1,112 @@
+package parse
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiscoverFindsAlternateFeedLinks(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><head>
+			<link rel="alternate" type="application/rss+xml" href="/feed.xml">
+			<link rel="alternate" type="text/html" href="/ignored.html">
+		</head></html>`))
+	}))
+	defer srv.Close()
+
+	feeds, err := Discover(context.Background(), srv.URL, ParseOptions{})
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+	if len(feeds) != 1 || !strings.HasSuffix(feeds[0], "/feed.xml") {
+		t.Fatalf("Discover() = %v, want a single URL ending in /feed.xml", feeds)
+	}
+}
+
+func TestDiscoverRespectsMaxBodyBytes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("a", 1024)))
+	}))
+	defer srv.Close()
+
+	_, err := Discover(context.Background(), srv.URL, ParseOptions{MaxBodyBytes: 10})
+	if err == nil {
+		t.Fatal("Discover() error = nil, want byte limit error")
+	}
+}
+
+func TestParseReturnsDiscoveredFeedURL(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><head>
+			<link rel="alternate" type="application/rss+xml" href="/feed.xml">
+		</head></html>`))
+	})
+	mux.HandleFunc("/feed.xml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(`<?xml version="1.0"?><rss version="2.0"><channel>
+			<title>Example Feed</title>
+		</channel></rss>`))
+	})
+
+	items, discoveredFeedURL, err := Parse(context.Background(), srv.URL+"/page", ParseOptions{})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(items) != 0 {
+		t.Fatalf("Parse() items = %v, want none (feed has no items)", items)
+	}
+	if !strings.HasSuffix(discoveredFeedURL, "/feed.xml") {
+		t.Fatalf("Parse() discoveredFeedURL = %q, want a URL ending in /feed.xml", discoveredFeedURL)
+	}
+}
+
+func TestParseDetectsMutualAutodiscoveryCycle(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	mux.HandleFunc("/a", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><head><link rel="alternate" type="application/rss+xml" href="/b"></head></html>`))
+	})
+	mux.HandleFunc("/b", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><head><link rel="alternate" type="application/rss+xml" href="/a"></head></html>`))
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _, err := Parse(context.Background(), srv.URL+"/a", ParseOptions{})
+		if err == nil {
+			t.Error("Parse() error = nil, want a cycle-detected error")
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Parse() did not return, want mutual autodiscovery cycle to be detected instead of recursing forever")
+	}
+}
+
+func TestDiscoverRespectsCanceledContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html></html>"))
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := Discover(ctx, srv.URL, ParseOptions{})
+	if err == nil {
+		t.Fatal("Discover() error = nil, want context canceled error")
+	}
+}
@@ -0,0 +1,219 @@
+package parse
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Enrichment holds metadata recovered from a page's OpenGraph/Twitter Card
+// <meta> tags, embedded JSON-LD, and advertised oEmbed endpoint, used to
+// fill in gaps the feed itself left empty.
+type Enrichment struct {
+	Title     string
+	Image     string
+	Author    string
+	Published string
+	EmbedHTML string
+}
+
+// ldNewsArticle is the subset of schema.org NewsArticle JSON-LD this package
+// understands.
+type ldNewsArticle struct {
+	Type          string      `json:"@type"`
+	Headline      string      `json:"headline"`
+	DatePublished string      `json:"datePublished"`
+	Image         interface{} `json:"image"`
+	Author        interface{} `json:"author"`
+}
+
+// enrichFromHTML fetches pageUrl and extracts whatever OpenGraph, Twitter
+// Card, JSON-LD NewsArticle, and oEmbed metadata it can find. The request is
+// bound to ctx and opts' per-request timeout and body size cap, and
+// conditional on any ETag/Last-Modified this package has already cached for
+// pageUrl.
+func enrichFromHTML(ctx context.Context, pageUrl string, opts ParseOptions) (*Enrichment, error) {
+	opts = opts.withDefaults()
+
+	var state FeedState
+	if cache != nil {
+		state = cache.FeedState(pageUrl)
+	}
+
+	resp, err := conditionalGet(ctx, pageUrl, state, opts.PerRequestTimeout, opts.MaxBodyBytes)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return &Enrichment{}, nil
+	}
+
+	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Enrichment{}
+	e.mergeMetaTags(doc)
+	e.mergeJSONLD(doc)
+
+	if embedUrl, ok := oEmbedLink(doc, pageUrl); ok {
+		html, err := fetchOEmbedHTML(ctx, embedUrl, opts)
+		if err != nil {
+			log.Printf("Failed to fetch oEmbed for %v: %v", pageUrl, err)
+		} else {
+			e.EmbedHTML = html
+		}
+	}
+
+	if cache != nil {
+		cache.UpdateFeedState(pageUrl, nextFeedState(resp))
+	}
+
+	return e, nil
+}
+
+// mergeMetaTags fills in fields from OpenGraph (og:*) and Twitter Card
+// (twitter:*) meta tags, preferring OpenGraph when both are present.
+func (e *Enrichment) mergeMetaTags(doc *goquery.Document) {
+	meta := func(name string) string {
+		content, _ := doc.Find(`meta[property="` + name + `"]`).First().Attr("content")
+		if content == "" {
+			content, _ = doc.Find(`meta[name="` + name + `"]`).First().Attr("content")
+		}
+		return content
+	}
+
+	if v := meta("og:title"); v != "" {
+		e.Title = v
+	} else if v := meta("twitter:title"); v != "" {
+		e.Title = v
+	}
+
+	if v := meta("og:image"); v != "" {
+		e.Image = v
+	} else if v := meta("twitter:image"); v != "" {
+		e.Image = v
+	}
+
+	if v := meta("article:published_time"); v != "" {
+		e.Published = v
+	}
+	if v := meta("article:author"); v != "" {
+		e.Author = v
+	}
+}
+
+// mergeJSONLD fills in any fields left empty by mergeMetaTags from the
+// first schema.org NewsArticle block found in the page's JSON-LD.
+func (e *Enrichment) mergeJSONLD(doc *goquery.Document) {
+	doc.Find(`script[type="application/ld+json"]`).EachWithBreak(func(i int, s *goquery.Selection) bool {
+		var article ldNewsArticle
+		if err := json.Unmarshal([]byte(s.Text()), &article); err != nil {
+			return true // keep looking at the next block
+		}
+		if article.Type != "NewsArticle" {
+			return true
+		}
+
+		if e.Title == "" {
+			e.Title = article.Headline
+		}
+		if e.Published == "" {
+			e.Published = article.DatePublished
+		}
+		if e.Image == "" {
+			e.Image = firstString(article.Image)
+		}
+		if e.Author == "" {
+			e.Author = authorName(article.Author)
+		}
+
+		return false // NewsArticle found, stop looking
+	})
+}
+
+// firstString handles JSON-LD's "image" field, which may be a single URL
+// string or an array of them.
+func firstString(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case []interface{}:
+		if len(t) > 0 {
+			if s, ok := t[0].(string); ok {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// authorName handles JSON-LD's "author" field, which may be a plain name
+// or a Person/Organization object with a "name" property.
+func authorName(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case map[string]interface{}:
+		if name, ok := t["name"].(string); ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// oEmbedLink looks for a <link rel="alternate" type="application/json+oembed">
+// tag and resolves its href against pageUrl.
+func oEmbedLink(doc *goquery.Document, pageUrl string) (string, bool) {
+	href, ok := doc.Find(`link[type="application/json+oembed"]`).First().Attr("href")
+	if !ok || href == "" {
+		return "", false
+	}
+	return resolveURL(pageUrl, href)
+}
+
+// fetchOEmbedHTML fetches an oEmbed endpoint and returns the rich "html"
+// field callers can render directly (e.g. a YouTube/Vimeo/Twitter embed).
+// The request is bound to ctx and opts' per-request timeout and body size
+// cap, and conditional on any ETag/Last-Modified this package has already
+// cached for embedUrl.
+func fetchOEmbedHTML(ctx context.Context, embedUrl string, opts ParseOptions) (string, error) {
+	var state FeedState
+	if cache != nil {
+		state = cache.FeedState(embedUrl)
+	}
+
+	resp, err := conditionalGet(ctx, embedUrl, state, opts.PerRequestTimeout, opts.MaxBodyBytes)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return state.CachedText, nil
+	}
+
+	var body struct {
+		HTML string `json:"html"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", err
+	}
+
+	html := strings.TrimSpace(body.HTML)
+
+	if cache != nil {
+		next := nextFeedState(resp)
+		next.CachedText = html
+		cache.UpdateFeedState(embedUrl, next)
+	}
+
+	return html, nil
+}
@@ -0,0 +1,90 @@
+package parse
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEnrichFromHTMLReadsMetaTags(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><head>
+			<meta property="og:title" content="A Title">
+			<meta property="og:image" content="https://example.com/img.png">
+			<meta property="article:author" content="Jane Doe">
+		</head></html>`))
+	}))
+	defer srv.Close()
+
+	e, err := enrichFromHTML(context.Background(), srv.URL, ParseOptions{})
+	if err != nil {
+		t.Fatalf("enrichFromHTML() error = %v", err)
+	}
+	if e.Title != "A Title" || e.Image != "https://example.com/img.png" || e.Author != "Jane Doe" {
+		t.Fatalf("enrichFromHTML() = %+v, want Title/Image/Author populated from og: tags", e)
+	}
+}
+
+func TestEnrichFromHTMLRespectsMaxBodyBytes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><head><meta property="og:title" content="padding padding padding"></head></html>`))
+	}))
+	defer srv.Close()
+
+	_, err := enrichFromHTML(context.Background(), srv.URL, ParseOptions{MaxBodyBytes: 10})
+	if err == nil {
+		t.Fatal("enrichFromHTML() error = nil, want byte limit error")
+	}
+}
+
+func TestEnrichFromHTMLSendsConditionalRequestAndCachesETag(t *testing.T) {
+	var requests, conditional int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			conditional++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`<html><head><meta property="og:title" content="A Title"></head></html>`))
+	}))
+	defer srv.Close()
+
+	c := newFakeCache()
+	SetCache(c)
+	defer SetCache(nil)
+
+	if _, err := enrichFromHTML(context.Background(), srv.URL, ParseOptions{}); err != nil {
+		t.Fatalf("enrichFromHTML() first call error = %v", err)
+	}
+	if got := c.FeedState(srv.URL).ETag; got != `"v1"` {
+		t.Fatalf("cached ETag = %q, want %q", got, `"v1"`)
+	}
+
+	if _, err := enrichFromHTML(context.Background(), srv.URL, ParseOptions{}); err != nil {
+		t.Fatalf("enrichFromHTML() second call error = %v", err)
+	}
+	if requests != 2 {
+		t.Fatalf("requests = %d, want 2", requests)
+	}
+	if conditional != 1 {
+		t.Fatalf("conditional requests = %d, want 1 (second call sends If-None-Match)", conditional)
+	}
+}
+
+func TestFetchOEmbedHTMLRespectsCanceledContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"html": "<iframe></iframe>"}`))
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := fetchOEmbedHTML(ctx, srv.URL, ParseOptions{})
+	if err == nil {
+		t.Fatal("fetchOEmbedHTML() error = nil, want context canceled error")
+	}
+}
@@ -0,0 +1,196 @@
+package parse
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	goose "github.com/advancedlogic/GoOse"
+	readability "github.com/go-shiori/go-readability"
+)
+
+var g = goose.New()
+
+// Article is the structured result of extracting a page's main content,
+// regardless of which ArticleExtractor produced it.
+type Article struct {
+	Text          string
+	HTML          string
+	TopImage      string
+	Images        []string
+	Byline        string
+	PublishedTime time.Time
+}
+
+// ArticleExtractor extracts an Article from the page at url. Implementations
+// vary in which sites they handle well; see ChainExtractor and
+// RegisterExtractor for combining and selecting between them. ctx and opts
+// bound and size-limit any network request the extractor makes.
+type ArticleExtractor interface {
+	Name() string
+	Extract(ctx context.Context, url string, opts ParseOptions) (*Article, error)
+}
+
+// gooseExtractor is the original GoOse-based extraction path.
+type gooseExtractor struct{}
+
+func (gooseExtractor) Name() string { return "goose" }
+
+// Extract ignores ctx and opts: GoOse has no context- or size-bounded fetch
+// path of its own.
+func (gooseExtractor) Extract(ctx context.Context, url string, opts ParseOptions) (*Article, error) {
+	article, err := g.ExtractFromURL(url)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Article{
+		Text:     article.CleanedText,
+		TopImage: article.TopImage,
+	}, nil
+}
+
+// readabilityExtractor wraps go-readability, which tends to do better than
+// GoOse on sites with unconventional article markup.
+type readabilityExtractor struct{}
+
+func (readabilityExtractor) Name() string { return "readability" }
+
+func (readabilityExtractor) Extract(ctx context.Context, pageUrl string, opts ParseOptions) (*Article, error) {
+	opts = opts.withDefaults()
+
+	parsed, err := url.Parse(pageUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	var state FeedState
+	if cache != nil {
+		state = cache.FeedState(pageUrl)
+	}
+
+	resp, err := conditionalGet(ctx, pageUrl, state, opts.PerRequestTimeout, opts.MaxBodyBytes)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return &Article{Text: state.CachedText}, nil
+	}
+
+	article, err := readability.FromReader(resp.Body, parsed)
+	if err != nil {
+		return nil, err
+	}
+
+	if cache != nil {
+		next := nextFeedState(resp)
+		next.CachedText = article.TextContent
+		cache.UpdateFeedState(pageUrl, next)
+	}
+
+	return &Article{
+		Text:          article.TextContent,
+		HTML:          article.Content,
+		TopImage:      article.Image,
+		Byline:        article.Byline,
+		PublishedTime: timeOrZero(article.PublishedTime),
+	}, nil
+}
+
+func timeOrZero(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
+
+// goqueryExtractor picks the largest <article> element on the page,
+// mirroring the heuristic GetExtendedArticle has always used.
+type goqueryExtractor struct{}
+
+func (goqueryExtractor) Name() string { return "goquery" }
+
+func (goqueryExtractor) Extract(ctx context.Context, pageUrl string, opts ParseOptions) (*Article, error) {
+	html, err := GetExtendedArticle(ctx, pageUrl, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Article{Text: html, HTML: html}, nil
+}
+
+// ChainExtractor tries each Extractor in order and keeps whichever returns
+// the longest cleaned text, so one extractor doing poorly on a given
+// domain doesn't leave the article empty.
+type ChainExtractor struct {
+	Extractors []ArticleExtractor
+}
+
+func (c ChainExtractor) Name() string { return "chain" }
+
+func (c ChainExtractor) Extract(ctx context.Context, url string, opts ParseOptions) (*Article, error) {
+	var best *Article
+	var lastErr error
+
+	for _, e := range c.Extractors {
+		article, err := e.Extract(ctx, url, opts)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if best == nil || len(article.Text) > len(best.Text) {
+			best = article
+		}
+	}
+
+	if best == nil {
+		return nil, lastErr
+	}
+	return best, nil
+}
+
+// defaultExtractor is used for any domain with no RegisterExtractor override.
+var defaultExtractor ArticleExtractor = ChainExtractor{
+	Extractors: []ArticleExtractor{gooseExtractor{}, readabilityExtractor{}, goqueryExtractor{}},
+}
+
+var (
+	extractorsMu       sync.RWMutex
+	extractorsByDomain = map[string]ArticleExtractor{}
+)
+
+// RegisterExtractor makes e the extractor used for any URL whose host
+// matches domain exactly (e.g. "www.nytimes.com"), overriding
+// defaultExtractor for that domain.
+func RegisterExtractor(domain string, e ArticleExtractor) {
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+	extractorsByDomain[domain] = e
+}
+
+// extractorFor returns the extractor registered for pageUrl's host, falling
+// back to defaultExtractor if none was registered or the URL can't be
+// parsed.
+func extractorFor(pageUrl string) ArticleExtractor {
+	parsed, err := url.Parse(pageUrl)
+	if err != nil {
+		return defaultExtractor
+	}
+
+	extractorsMu.RLock()
+	defer extractorsMu.RUnlock()
+
+	if e, ok := extractorsByDomain[parsed.Host]; ok {
+		return e
+	}
+	return defaultExtractor
+}
+
+// extractArticle extracts url's main content using the extractor registered
+// for its domain, or defaultExtractor otherwise.
+func extractArticle(ctx context.Context, url string, opts ParseOptions) (*Article, error) {
+	return extractorFor(url).Extract(ctx, url, opts)
+}
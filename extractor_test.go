@@ -0,0 +1,101 @@
+package parse
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestReadabilityExtractorRespectsMaxBodyBytes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("a", 1024)))
+	}))
+	defer srv.Close()
+
+	_, err := readabilityExtractor{}.Extract(context.Background(), srv.URL, ParseOptions{MaxBodyBytes: 10})
+	if err == nil {
+		t.Fatal("Extract() error = nil, want byte limit error")
+	}
+}
+
+func TestReadabilityExtractorRespectsCanceledContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><body><article>hi</article></body></html>"))
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := readabilityExtractor{}.Extract(ctx, srv.URL, ParseOptions{})
+	if err == nil {
+		t.Fatal("Extract() error = nil, want context canceled error")
+	}
+}
+
+func TestReadabilityExtractorServesCachedTextOn304(t *testing.T) {
+	var requests, notModified int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`<html><body><article>first fetch content</article></body></html>`))
+	}))
+	defer srv.Close()
+
+	c := newFakeCache()
+	SetCache(c)
+	defer SetCache(nil)
+
+	first, err := readabilityExtractor{}.Extract(context.Background(), srv.URL, ParseOptions{})
+	if err != nil {
+		t.Fatalf("Extract() first call error = %v", err)
+	}
+
+	second, err := readabilityExtractor{}.Extract(context.Background(), srv.URL, ParseOptions{})
+	if err != nil {
+		t.Fatalf("Extract() second call error = %v", err)
+	}
+	if requests != 2 {
+		t.Fatalf("requests = %d, want 2", requests)
+	}
+	if notModified != 1 {
+		t.Fatalf("304 responses = %d, want 1 (second call answered from cache)", notModified)
+	}
+	if second.Text != first.Text {
+		t.Fatalf("Extract() second.Text = %q, want cached %q", second.Text, first.Text)
+	}
+}
+
+func TestChainExtractorKeepsLongestArticle(t *testing.T) {
+	short := stubExtractor{name: "short", article: &Article{Text: "hi"}}
+	long := stubExtractor{name: "long", article: &Article{Text: "hello world"}}
+
+	c := ChainExtractor{Extractors: []ArticleExtractor{short, long}}
+
+	article, err := c.Extract(context.Background(), "http://example.com", ParseOptions{})
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	if article.Text != "hello world" {
+		t.Fatalf("Extract() = %q, want %q", article.Text, "hello world")
+	}
+}
+
+type stubExtractor struct {
+	name    string
+	article *Article
+	err     error
+}
+
+func (s stubExtractor) Name() string { return s.name }
+
+func (s stubExtractor) Extract(ctx context.Context, url string, opts ParseOptions) (*Article, error) {
+	return s.article, s.err
+}
@@ -0,0 +1,143 @@
+package parse
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// tr is the default RoundTripper used by client. Callers needing an
+// on-disk response cache (e.g. an httpcache.Transport) can swap it in via
+// SetTransport.
+var tr http.RoundTripper = &http.Transport{
+	IdleConnTimeout: 5 * time.Second,
+}
+
+var client = &http.Client{
+	Transport: tr,
+}
+
+// SetTransport lets callers plug in an alternate http.RoundTripper, such as
+// an httpcache.Transport backed by a disk cache directory.
+func SetTransport(rt http.RoundTripper) {
+	tr = rt
+	client = &http.Client{Transport: rt}
+}
+
+// conditionalGet performs a GET against url, attaching If-None-Match /
+// If-Modified-Since headers when state carries a prior ETag or
+// Last-Modified value, so unchanged resources come back as a 304 without a
+// body. The request is bound to ctx, optionally narrowed by timeout, and
+// the response body is capped at maxBytes so a hostile server can't
+// exhaust memory. A zero timeout or maxBytes leaves that limit unset.
+func conditionalGet(ctx context.Context, url string, state FeedState, timeout time.Duration, maxBytes int64) (*http.Response, error) {
+	cancel := func() {}
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	if state.ETag != "" {
+		req.Header.Set("If-None-Match", state.ETag)
+	}
+	if state.LastModified != "" {
+		req.Header.Set("If-Modified-Since", state.LastModified)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	// The timeout must stay live for as long as the caller is still
+	// reading the body, so tie cancel to Close rather than firing it here.
+	resp.Body = limitBody(&cancelOnClose{ReadCloser: resp.Body, cancel: cancel}, maxBytes)
+	return resp, nil
+}
+
+// cancelOnClose runs cancel when the wrapped body is closed, releasing a
+// conditionalGet timeout once the caller is done reading.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	defer c.cancel()
+	return c.ReadCloser.Close()
+}
+
+// nextFeedState derives the FeedState to persist after a successful fetch,
+// carrying forward the caching headers the server returned.
+func nextFeedState(resp *http.Response) FeedState {
+	return FeedState{
+		LastFetch:    time.Now(),
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+}
+
+// drainAndClose discards any remaining body bytes and closes it, so the
+// underlying connection can be reused by the transport's connection pool.
+func drainAndClose(body io.ReadCloser) {
+	io.Copy(io.Discard, body)
+	body.Close()
+}
+
+// maxBytesReader wraps a response body so reads past the limit fail
+// instead of silently allocating without bound, mirroring
+// http.MaxBytesReader for client-side use. Each Read asks the underlying
+// reader for one byte more than remaining so a body of exactly limit bytes
+// is never mistaken for one that overflows it; the error only fires once
+// that extra byte is actually read.
+type maxBytesReader struct {
+	r         io.ReadCloser
+	limit     int64
+	remaining int64
+	err       error
+}
+
+// limitBody returns r unchanged when n <= 0; otherwise it caps further
+// reads to n bytes.
+func limitBody(r io.ReadCloser, n int64) io.ReadCloser {
+	if n <= 0 {
+		return r
+	}
+	return &maxBytesReader{r: r, limit: n, remaining: n}
+}
+
+func (m *maxBytesReader) Read(p []byte) (int, error) {
+	if m.err != nil {
+		return 0, m.err
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if int64(len(p)) > m.remaining+1 {
+		p = p[:m.remaining+1]
+	}
+
+	n, err := m.r.Read(p)
+	if int64(n) <= m.remaining {
+		m.remaining -= int64(n)
+		m.err = err
+		return n, err
+	}
+
+	n = int(m.remaining)
+	m.remaining = 0
+	m.err = fmt.Errorf("response body exceeds %d byte limit", m.limit)
+	return n, m.err
+}
+
+func (m *maxBytesReader) Close() error {
+	return m.r.Close()
+}
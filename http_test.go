@@ -0,0 +1,58 @@
+package parse
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+type nopCloser struct {
+	io.Reader
+}
+
+func (nopCloser) Close() error { return nil }
+
+func TestLimitBodyExactLimitSucceeds(t *testing.T) {
+	body := limitBody(nopCloser{strings.NewReader("hello")}, 5)
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v, want nil", err)
+	}
+	if string(data) != "hello" {
+		t.Fatalf("ReadAll() = %q, want %q", data, "hello")
+	}
+}
+
+func TestLimitBodyOverLimitFails(t *testing.T) {
+	body := limitBody(nopCloser{strings.NewReader("hello world")}, 5)
+
+	_, err := io.ReadAll(body)
+	if err == nil {
+		t.Fatal("ReadAll() error = nil, want byte limit error")
+	}
+}
+
+func TestLimitBodyUnderLimitSucceeds(t *testing.T) {
+	body := limitBody(nopCloser{strings.NewReader("hi")}, 5)
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v, want nil", err)
+	}
+	if string(data) != "hi" {
+		t.Fatalf("ReadAll() = %q, want %q", data, "hi")
+	}
+}
+
+func TestLimitBodyZeroLeavesUnbounded(t *testing.T) {
+	body := limitBody(nopCloser{strings.NewReader("anything")}, 0)
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v, want nil", err)
+	}
+	if string(data) != "anything" {
+		t.Fatalf("ReadAll() = %q, want %q", data, "anything")
+	}
+}
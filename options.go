@@ -0,0 +1,62 @@
+package parse
+
+import "time"
+
+// defaultConcurrency, defaultPerRequestTimeout, defaultMaxBodyBytes and
+// defaultMaxRetries back DefaultParseOptions, which withDefaults substitutes
+// when Parse or a single-request helper (GetExtendedArticle, Discover) is
+// given a zero ParseOptions.
+const (
+	defaultConcurrency       = 4
+	defaultPerRequestTimeout = 15 * time.Second
+	defaultMaxBodyBytes      = 10 << 20 // 10MB
+	defaultMaxRetries        = 1
+)
+
+// ParseOptions controls how Parse fetches a feed and its items.
+type ParseOptions struct {
+	// Concurrency caps how many items are fetched at once.
+	Concurrency int
+	// PerRequestTimeout bounds each individual HTTP request. Zero means no
+	// per-request timeout beyond ctx.
+	PerRequestTimeout time.Duration
+	// MaxBodyBytes caps how many bytes of a response body are read. Zero
+	// means unbounded.
+	MaxBodyBytes int64
+	// MaxRetries is how many extra attempts a failed item extraction gets
+	// before the item is dropped from the result instead of being returned
+	// with an empty extended body. Zero means fail fast: no retries.
+	MaxRetries int
+	// SortBy controls the order of the returned items. Zero value is
+	// SortFeedOrder.
+	SortBy SortOrder
+}
+
+// DefaultParseOptions returns the options Parse uses when none are given.
+func DefaultParseOptions() ParseOptions {
+	return ParseOptions{
+		Concurrency:       defaultConcurrency,
+		PerRequestTimeout: defaultPerRequestTimeout,
+		MaxBodyBytes:      defaultMaxBodyBytes,
+		MaxRetries:        defaultMaxRetries,
+		SortBy:            SortFeedOrder,
+	}
+}
+
+// withDefaults returns DefaultParseOptions() when o is the zero value
+// (callers passing ParseOptions{} meaning "I have no opinion"), and returns
+// o unchanged otherwise. This is what lets each field's documented
+// "zero means ..." behavior actually reach Parse when a caller sets it
+// explicitly, instead of being silently replaced by the default.
+//
+// Concurrency is the one exception: it has no "zero means X" meaning of its
+// own, so a non-positive value here always falls back to the default.
+func (o ParseOptions) withDefaults() ParseOptions {
+	if o == (ParseOptions{}) {
+		return DefaultParseOptions()
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = DefaultParseOptions().Concurrency
+	}
+	return o
+}
@@ -0,0 +1,40 @@
+package parse
+
+import "testing"
+
+func TestWithDefaultsFillsZeroValue(t *testing.T) {
+	got := ParseOptions{}.withDefaults()
+	want := DefaultParseOptions()
+	if got != want {
+		t.Fatalf("withDefaults() = %+v, want %+v", got, want)
+	}
+}
+
+func TestWithDefaultsRespectsExplicitZeroFields(t *testing.T) {
+	opts := ParseOptions{Concurrency: 2, MaxRetries: 0, PerRequestTimeout: 0, MaxBodyBytes: 0}
+
+	got := opts.withDefaults()
+
+	if got.MaxRetries != 0 {
+		t.Errorf("MaxRetries = %d, want 0 (explicit fail-fast preserved)", got.MaxRetries)
+	}
+	if got.PerRequestTimeout != 0 {
+		t.Errorf("PerRequestTimeout = %v, want 0 (explicit no-timeout preserved)", got.PerRequestTimeout)
+	}
+	if got.MaxBodyBytes != 0 {
+		t.Errorf("MaxBodyBytes = %d, want 0 (explicit unbounded preserved)", got.MaxBodyBytes)
+	}
+}
+
+func TestWithDefaultsFillsNonPositiveConcurrency(t *testing.T) {
+	opts := ParseOptions{Concurrency: 0, MaxRetries: 2}
+
+	got := opts.withDefaults()
+
+	if got.Concurrency != DefaultParseOptions().Concurrency {
+		t.Errorf("Concurrency = %d, want default %d", got.Concurrency, DefaultParseOptions().Concurrency)
+	}
+	if got.MaxRetries != 2 {
+		t.Errorf("MaxRetries = %d, want 2 (untouched)", got.MaxRetries)
+	}
+}
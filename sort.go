@@ -0,0 +1,51 @@
+package parse
+
+import (
+	"sort"
+	"time"
+)
+
+// SortOrder controls how Parse orders the items it returns.
+type SortOrder int
+
+const (
+	// SortFeedOrder keeps the order items appeared in the source feed.
+	// This is the default.
+	SortFeedOrder SortOrder = iota
+	// SortPublishedDesc orders items by published date, newest first.
+	// Items with no parseable published date sort last, in feed order.
+	SortPublishedDesc
+	// SortID orders items by their generated id.
+	SortID
+)
+
+// resultItem pairs an extracted RrssFeed with the parsed time used to sort
+// it, since RrssFeed.Published is a string in whatever format the feed
+// used.
+type resultItem struct {
+	feed      RrssFeed
+	published time.Time
+}
+
+func sortResults(items []resultItem, sortBy SortOrder) {
+	switch sortBy {
+	case SortPublishedDesc:
+		sort.SliceStable(items, func(i, j int) bool {
+			pi, pj := items[i].published, items[j].published
+			if pi.IsZero() || pj.IsZero() {
+				// A zero time never sorts before anything (so it sorts
+				// last, in feed order), but a dated item must still
+				// compare as before a zero one for the ordering to stay
+				// transitive.
+				return !pi.IsZero() && pj.IsZero()
+			}
+			return pi.After(pj)
+		})
+	case SortID:
+		sort.SliceStable(items, func(i, j int) bool {
+			return items[i].feed.Id < items[j].feed.Id
+		})
+	default:
+		// SortFeedOrder: items are already in feed order.
+	}
+}
@@ -0,0 +1,42 @@
+package parse
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSortResultsPublishedDesc(t *testing.T) {
+	items := []resultItem{
+		{feed: RrssFeed{Id: "A"}},
+		{feed: RrssFeed{Id: "B"}, published: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{feed: RrssFeed{Id: "C"}},
+		{feed: RrssFeed{Id: "D"}, published: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	sortResults(items, SortPublishedDesc)
+
+	got := make([]string, len(items))
+	for i, item := range items {
+		got[i] = item.feed.Id
+	}
+
+	want := []string{"D", "B", "A", "C"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("sortResults() order = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestSortResultsID(t *testing.T) {
+	items := []resultItem{
+		{feed: RrssFeed{Id: "B"}},
+		{feed: RrssFeed{Id: "A"}},
+	}
+
+	sortResults(items, SortID)
+
+	if items[0].feed.Id != "A" || items[1].feed.Id != "B" {
+		t.Fatalf("sortResults() order = %v, want [A B]", items)
+	}
+}